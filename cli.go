@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// resolveResult reports how resolveBookmark matched a user-supplied
+// name/path/index against the bookmark set.
+type resolveResult int
+
+const (
+	resolveNotFound resolveResult = iota
+	resolveFound
+	resolveAmbiguous
+)
+
+// resolveBookmark finds the bookmark a scripting command refers to: a
+// 1-based index into bookmarks (as printed by `bk list`), an exact name,
+// or an exact path, in that order.
+func resolveBookmark(bookmarks []Bookmark, query string) (Bookmark, resolveResult) {
+	if n, err := strconv.Atoi(query); err == nil {
+		if n >= 1 && n <= len(bookmarks) {
+			return bookmarks[n-1], resolveFound
+		}
+		return Bookmark{}, resolveNotFound
+	}
+
+	var nameMatches []Bookmark
+	for _, b := range bookmarks {
+		if b.Name == query {
+			nameMatches = append(nameMatches, b)
+		}
+	}
+	if len(nameMatches) == 1 {
+		return nameMatches[0], resolveFound
+	}
+	if len(nameMatches) > 1 {
+		return Bookmark{}, resolveAmbiguous
+	}
+
+	for _, b := range bookmarks {
+		if b.Path == query {
+			return b, resolveFound
+		}
+	}
+
+	return Bookmark{}, resolveNotFound
+}
+
+func loadSortedBookmarks(store Store) []Bookmark {
+	config, err := store.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading bookmarks: %v\n", err)
+		os.Exit(1)
+	}
+	sortBookmarks(config.Bookmarks, sortFrecency, "")
+	return config.Bookmarks
+}
+
+// reportResolveFailure prints an error and exits with the scripting exit
+// code convention: 1 for not found, 2 for ambiguous.
+func reportResolveFailure(query string, result resolveResult) {
+	switch result {
+	case resolveAmbiguous:
+		fmt.Fprintf(os.Stderr, "Ambiguous bookmark: %s\n", query)
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "Bookmark not found: %s\n", query)
+		os.Exit(1)
+	}
+}
+
+// cmdList implements `bk list [--json]`.
+func cmdList(store Store, args []string) {
+	jsonOut := false
+	for _, a := range args {
+		if a == "--json" {
+			jsonOut = true
+		}
+	}
+
+	bookmarks := loadSortedBookmarks(store)
+
+	if jsonOut {
+		data, err := json.MarshalIndent(Config{Bookmarks: bookmarks}, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding bookmarks: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	for i, b := range bookmarks {
+		name := b.Name
+		if name == "" {
+			name = "-"
+		}
+		fmt.Printf("%d\t%s\t%s\n", i+1, name, b.Path)
+	}
+}
+
+// cmdGo implements `bk go <name|path|index>`: prints the resolved path to
+// stdout with no TUI, for use in shell functions like `cd "$(bk go x)"`.
+func cmdGo(store Store, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: bk go <name|path|index>")
+		os.Exit(1)
+	}
+
+	bookmarks := loadSortedBookmarks(store)
+	b, result := resolveBookmark(bookmarks, args[0])
+	if result != resolveFound {
+		reportResolveFailure(args[0], result)
+		return
+	}
+
+	if err := store.IncrementCount(b.Path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error recording visit: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(b.Path)
+}
+
+// cmdRm implements `bk rm <name|path|index>`.
+func cmdRm(store Store, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: bk rm <name|path|index>")
+		os.Exit(1)
+	}
+
+	bookmarks := loadSortedBookmarks(store)
+	b, result := resolveBookmark(bookmarks, args[0])
+	if result != resolveFound {
+		reportResolveFailure(args[0], result)
+		return
+	}
+
+	if err := store.Delete(b.Path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error deleting bookmark: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Deleted bookmark: %s\n", displayName(b))
+}
+
+// cmdRename implements `bk rename <old> <new>`.
+func cmdRename(store Store, args []string) {
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: bk rename <old> <new>")
+		os.Exit(1)
+	}
+
+	bookmarks := loadSortedBookmarks(store)
+	b, result := resolveBookmark(bookmarks, args[0])
+	if result != resolveFound {
+		reportResolveFailure(args[0], result)
+		return
+	}
+
+	b.Name = args[1]
+	if err := store.Update(b.Path, b); err != nil {
+		fmt.Fprintf(os.Stderr, "Error renaming bookmark: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Renamed %s to %s\n", args[0], args[1])
+}
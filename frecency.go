@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// maxVisitHistory bounds the ring buffer of recent visit timestamps kept
+// per bookmark; older visits still count toward Count but stop
+// contributing to the frecency score.
+const maxVisitHistory = 8
+
+// recordVisit bumps a bookmark's use count and appends a visit timestamp,
+// trimming the ring buffer to maxVisitHistory entries.
+func recordVisit(b *Bookmark, at time.Time) {
+	b.Count++
+	b.LastUsed = at
+	b.Visits = append(b.Visits, at)
+	if len(b.Visits) > maxVisitHistory {
+		b.Visits = b.Visits[len(b.Visits)-maxVisitHistory:]
+	}
+}
+
+// visitWeight buckets a visit's age the way Firefox's Places DB does,
+// decaying the contribution of older visits in steps rather than smoothly.
+func visitWeight(age time.Duration) float64 {
+	switch {
+	case age < time.Hour:
+		return 100
+	case age < 24*time.Hour:
+		return 70
+	case age < 7*24*time.Hour:
+		return 50
+	case age < 30*24*time.Hour:
+		return 30
+	default:
+		return 10
+	}
+}
+
+// frecencyScore blends visit frequency and recency: each recorded visit
+// contributes a weight that decays by age bucket, and an exact match
+// against the active filter multiplies the score by 1.5 so a bookmark named
+// exactly what was typed wins ties. A bookmark with no recorded visit
+// scores 0 with or without the boost.
+func frecencyScore(b Bookmark, now time.Time, exactMatch string) float64 {
+	visits := b.Visits
+	if len(visits) == 0 && !b.LastUsed.IsZero() {
+		visits = []time.Time{b.LastUsed}
+	}
+
+	score := 0.0
+	for _, v := range visits {
+		score += visitWeight(now.Sub(v))
+	}
+	if exactMatch != "" && strings.EqualFold(b.Name, exactMatch) {
+		score *= 1.5
+	}
+	return score
+}
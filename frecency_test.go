@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrecencyScoreWeightsRecentVisitsHigher(t *testing.T) {
+	now := time.Now()
+	recent := Bookmark{Visits: []time.Time{now.Add(-10 * time.Minute)}}
+	stale := Bookmark{Visits: []time.Time{now.Add(-60 * 24 * time.Hour)}}
+
+	if frecencyScore(recent, now, "") <= frecencyScore(stale, now, "") {
+		t.Fatalf("recent visit should score higher than a stale one")
+	}
+}
+
+func TestFrecencyScoreSumsMultipleVisits(t *testing.T) {
+	now := time.Now()
+	single := Bookmark{Visits: []time.Time{now.Add(-time.Minute)}}
+	multi := Bookmark{Visits: []time.Time{now.Add(-time.Minute), now.Add(-2 * time.Minute)}}
+
+	if frecencyScore(multi, now, "") <= frecencyScore(single, now, "") {
+		t.Fatalf("multiple recent visits should outscore a single visit")
+	}
+}
+
+func TestFrecencyScoreFallsBackToLastUsed(t *testing.T) {
+	now := time.Now()
+	b := Bookmark{LastUsed: now.Add(-time.Hour)}
+	if frecencyScore(b, now, "") == 0 {
+		t.Fatalf("expected a non-zero score from LastUsed when Visits is empty")
+	}
+}
+
+func TestFrecencyScoreExactMatchBoost(t *testing.T) {
+	now := time.Now()
+	b := Bookmark{Name: "proj", Visits: []time.Time{now.Add(-time.Minute)}}
+	if frecencyScore(b, now, "proj") <= frecencyScore(b, now, "") {
+		t.Fatalf("exact name match should boost the score")
+	}
+}
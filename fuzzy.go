@@ -0,0 +1,204 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	fuzzyScoreMatch       = 16
+	fuzzyBonusBoundary    = 10
+	fuzzyBonusCamelCase   = 7
+	fuzzyBonusConsecutive = 4
+	fuzzyBonusPrefix      = 12
+	fuzzyGapPenalty       = -2
+)
+
+// negInf stands in for "no valid alignment reaches this cell" in the
+// fuzzyMatch DP tables. Using a large-but-finite sentinel (rather than
+// math.Inf) keeps the arithmetic in the recurrence simple and overflow-free.
+const negInf = -(1 << 30)
+
+// dpCell is one state in fuzzyMatch's DP: the best score of a partial
+// alignment, and the predecessor state it was reached from (for
+// backtracking the winning alignment afterwards).
+type dpCell struct {
+	score             int
+	parentJ, parentR int
+}
+
+// fuzzyMatch scores how well pattern fuzzy-matches text, fzf-style. Unlike
+// a greedy leftmost scan, it finds the subsequence alignment of pattern's
+// runes in text that maximizes score via dynamic programming, so a
+// repeated character earlier in text can't force a worse-scoring alignment
+// just because it was found first. Rewards consecutive runs, matches right
+// after a path separator or word boundary, camelCase boundaries, and an
+// overall prefix match; penalizes gaps between matched runes. ok is false
+// if pattern is not a subsequence of text at all. Positions are rune
+// offsets into text.
+func fuzzyMatch(pattern, text string) (score int, positions []int, ok bool) {
+	p := []rune(strings.ToLower(pattern))
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	t := []rune(text)
+	tl := []rune(strings.ToLower(text))
+	m, n := len(p), len(t)
+	if n < m {
+		return 0, nil, false
+	}
+
+	// dp[i][j][r] is the best score of aligning p[0:i+1] with p[i] matched
+	// at text position j, ending a consecutive run of exactly r matches
+	// (r==0 means this match isn't adjacent to the previous one). Every
+	// achievable run length is tracked per cell, not just the
+	// highest-scoring one: a slightly lower-scoring run can still be the
+	// predecessor that wins once its larger consecutive bonus compounds on
+	// a later match, which is what makes the DP exact rather than greedy.
+	// bestH/bestR collapse each cell to its best (score, run length) pair,
+	// for the gap transition, which doesn't care which run length a
+	// predecessor ended with.
+	dp := make([][][]dpCell, m)
+	bestH := make([][]int, m)
+	bestR := make([][]int, m)
+	for i := range dp {
+		dp[i] = make([][]dpCell, n)
+		bestH[i] = make([]int, n)
+		bestR[i] = make([]int, n)
+		for j := range dp[i] {
+			dp[i][j] = make([]dpCell, i+1)
+			for r := range dp[i][j] {
+				dp[i][j][r] = dpCell{score: negInf}
+			}
+			bestH[i][j] = negInf
+		}
+	}
+
+	for i := 0; i < m; i++ {
+		// runningMax tracks max(bestH[i-1][k] - fuzzyGapPenalty*k) over
+		// k < j, letting the gap-penalty transition consider every earlier
+		// match of p[i-1] in O(n) total instead of rescanning for each j.
+		runningMax, runningMaxK := negInf, -1
+		for j := 0; j < n; j++ {
+			if j >= 1 && i > 0 && bestH[i-1][j-1] > negInf {
+				if val := bestH[i-1][j-1] - fuzzyGapPenalty*(j-1); val > runningMax {
+					runningMax, runningMaxK = val, j-1
+				}
+			}
+			if tl[j] != p[i] {
+				continue
+			}
+			bonus := matchBonus(t, j)
+
+			if i == 0 {
+				dp[i][j][0] = dpCell{score: bonus, parentJ: -1, parentR: -1}
+				bestH[i][j], bestR[i][j] = bonus, 0
+				continue
+			}
+
+			// r == 0: this match starts a fresh run, reached via a gap
+			// from the best-scoring state at an earlier text position,
+			// whatever run length that state ended with.
+			if runningMaxK != -1 {
+				dp[i][j][0] = dpCell{
+					score:   runningMax + fuzzyGapPenalty*j - fuzzyGapPenalty + bonus,
+					parentJ: runningMaxK,
+					parentR: bestR[i-1][runningMaxK],
+				}
+			}
+
+			// r >= 1: this match continues a consecutive run that ended
+			// with run length r-1 immediately before it, at j-1.
+			if j >= 1 {
+				for r := 1; r <= i; r++ {
+					prev := dp[i-1][j-1][r-1]
+					if prev.score <= negInf {
+						continue
+					}
+					dp[i][j][r] = dpCell{
+						score:   prev.score + fuzzyBonusConsecutive*r + bonus,
+						parentJ: j - 1,
+						parentR: r - 1,
+					}
+				}
+			}
+
+			for r, cell := range dp[i][j] {
+				if cell.score > bestH[i][j] {
+					bestH[i][j], bestR[i][j] = cell.score, r
+				}
+			}
+		}
+	}
+
+	bestJ, bestScore := -1, negInf
+	for j := 0; j < n; j++ {
+		if bestH[m-1][j] > bestScore {
+			bestScore, bestJ = bestH[m-1][j], j
+		}
+	}
+	if bestJ == -1 {
+		return 0, nil, false
+	}
+
+	positions = make([]int, m)
+	j, r := bestJ, bestR[m-1][bestJ]
+	for i := m - 1; i >= 0; i-- {
+		positions[i] = j
+		cell := dp[i][j][r]
+		j, r = cell.parentJ, cell.parentR
+	}
+
+	if strings.HasPrefix(strings.ToLower(string(t)), string(p)) {
+		bestScore += fuzzyBonusPrefix
+	}
+
+	return bestScore, positions, true
+}
+
+// matchBonus is the position-only part of a match's score: a boundary
+// bonus for matching right at the start of text, after a path/word
+// separator, or at a camelCase transition.
+func matchBonus(t []rune, j int) int {
+	bonus := fuzzyScoreMatch
+	switch {
+	case j == 0:
+		bonus += fuzzyBonusBoundary * 2
+	case isBoundaryRune(t[j-1]):
+		bonus += fuzzyBonusBoundary
+	case unicode.IsLower(t[j-1]) && unicode.IsUpper(t[j]):
+		bonus += fuzzyBonusCamelCase
+	}
+	return bonus
+}
+
+func isBoundaryRune(r rune) bool {
+	return r == '/' || r == '-' || r == '_' || r == ' ' || r == '.'
+}
+
+// renderHighlighted renders s rune by rune, styling runes whose position
+// in the wider match text (positions, offset by offset) matched the
+// active fuzzy filter with match, and everything else with base.
+func renderHighlighted(s string, positions []int, offset int, base, match lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(s)
+	}
+
+	matched := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		matched[p-offset] = true
+	}
+
+	var sb strings.Builder
+	for i, r := range []rune(s) {
+		if matched[i] {
+			sb.WriteString(match.Render(string(r)))
+		} else {
+			sb.WriteString(base.Render(string(r)))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// TestFuzzyMatchPrefersOptimalAlignmentOverLeftmostGreedy reproduces the
+// case where a pattern character repeats earlier in the text: a leftmost
+// greedy scan picks positions [1,4] (with a gap penalty), but [3,4] is a
+// consecutive, higher-scoring alignment.
+func TestFuzzyMatchPrefersOptimalAlignmentOverLeftmostGreedy(t *testing.T) {
+	score, positions, ok := fuzzyMatch("ab", "xaxabx")
+	if !ok {
+		t.Fatalf("expected a match")
+	}
+	if len(positions) != 2 || positions[0] != 3 || positions[1] != 4 {
+		t.Fatalf("positions = %v, want [3 4]", positions)
+	}
+	if score != 36 {
+		t.Fatalf("score = %d, want 36", score)
+	}
+}
+
+// TestFuzzyMatchDoesNotPanicWhenLaterRuneMatchesAtTextStart guards against
+// a regression where matching the pattern's 2nd+ rune at text position 0
+// indexed bestH[i-1][-1] and panicked.
+func TestFuzzyMatchDoesNotPanicWhenLaterRuneMatchesAtTextStart(t *testing.T) {
+	if _, _, ok := fuzzyMatch("ba", "ab"); ok {
+		t.Fatalf("expected no match: 'b' does not precede 'a' in \"ab\"")
+	}
+}
+
+// TestFuzzyMatchFindsGloballyOptimalAlignment verifies the DP picks the
+// alignment with the highest total score even when doing so requires a
+// lower-scoring intermediate match (here, (2,3,4) beats (1,3,4) only
+// because it keeps both matches consecutive, compounding the run bonus).
+func TestFuzzyMatchFindsGloballyOptimalAlignment(t *testing.T) {
+	score, positions, ok := fuzzyMatch("A/A", "bAa/a")
+	if !ok {
+		t.Fatalf("expected a match")
+	}
+	if len(positions) != 3 || positions[0] != 2 || positions[1] != 3 || positions[2] != 4 {
+		t.Fatalf("positions = %v, want [2 3 4]", positions)
+	}
+	if score != 70 {
+		t.Fatalf("score = %d, want 70", score)
+	}
+}
+
+func TestFuzzyMatchNoSubsequence(t *testing.T) {
+	if _, _, ok := fuzzyMatch("xyz", "abc"); ok {
+		t.Fatalf("expected no match")
+	}
+}
+
+func TestFuzzyMatchEmptyPattern(t *testing.T) {
+	score, positions, ok := fuzzyMatch("", "anything")
+	if !ok || score != 0 || positions != nil {
+		t.Fatalf("got score=%d positions=%v ok=%v, want 0 nil true", score, positions, ok)
+	}
+}
+
+// bruteForceFuzzyScore recomputes fuzzyMatch's score by exhaustively
+// trying every valid subsequence alignment, for use as an oracle in tests.
+func bruteForceFuzzyScore(pattern, text string) (best int, ok bool) {
+	p := []rune(strings.ToLower(pattern))
+	if len(p) == 0 {
+		return 0, true
+	}
+	t := []rune(text)
+	tl := []rune(strings.ToLower(text))
+	best = negInf
+
+	var rec func(pi, ti, prevMatched, consecutive, score int)
+	rec = func(pi, ti, prevMatched, consecutive, score int) {
+		if pi == len(p) {
+			ok = true
+			if score > best {
+				best = score
+			}
+			return
+		}
+		for j := ti; j < len(t); j++ {
+			if tl[j] != p[pi] {
+				continue
+			}
+			addScore := matchBonus(t, j)
+			nextConsecutive := 0
+			if prevMatched >= 0 {
+				if j == prevMatched+1 {
+					nextConsecutive = consecutive + 1
+					addScore += fuzzyBonusConsecutive * nextConsecutive
+				} else {
+					addScore += fuzzyGapPenalty * (j - prevMatched - 1)
+				}
+			}
+			rec(pi+1, j+1, j, nextConsecutive, score+addScore)
+		}
+	}
+	rec(0, 0, -1, 0, 0)
+	if ok && strings.HasPrefix(strings.ToLower(text), string(p)) {
+		best += fuzzyBonusPrefix
+	}
+	return best, ok
+}
+
+// TestFuzzyMatchAgainstBruteForce runs fuzzyMatch against a brute-force
+// oracle over many random short pattern/text pairs, the same way the
+// panic and sub-optimal-scoring regressions here were originally found.
+func TestFuzzyMatchAgainstBruteForce(t *testing.T) {
+	alphabet := []rune("abcAB/")
+	rng := rand.New(rand.NewSource(1))
+	randString := func(n int) string {
+		var sb strings.Builder
+		for i := 0; i < n; i++ {
+			sb.WriteRune(alphabet[rng.Intn(len(alphabet))])
+		}
+		return sb.String()
+	}
+
+	for i := 0; i < 500; i++ {
+		pattern := randString(1 + rng.Intn(3))
+		text := randString(1 + rng.Intn(6))
+
+		wantScore, wantOK := bruteForceFuzzyScore(pattern, text)
+		gotScore, _, gotOK := fuzzyMatch(pattern, text)
+
+		if gotOK != wantOK {
+			t.Fatalf("fuzzyMatch(%q, %q) ok = %v, want %v", pattern, text, gotOK, wantOK)
+		}
+		if gotOK && gotScore != wantScore {
+			t.Fatalf("fuzzyMatch(%q, %q) score = %d, want %d (brute force)", pattern, text, gotScore, wantScore)
+		}
+	}
+}
+
+func TestFuzzyMatchPrefixBonus(t *testing.T) {
+	prefixScore, _, ok := fuzzyMatch("ab", "abx")
+	if !ok {
+		t.Fatalf("expected a match")
+	}
+	midScore, _, ok := fuzzyMatch("ab", "xabx")
+	if !ok {
+		t.Fatalf("expected a match")
+	}
+	if prefixScore <= midScore {
+		t.Fatalf("prefix match (%d) should score higher than mid-string match (%d)", prefixScore, midScore)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -15,9 +17,52 @@ import (
 
 // Bookmark represents a saved directory path
 type Bookmark struct {
-	Path  string `json:"path"`
-	Name  string `json:"name,omitempty"`
-	Count int    `json:"count"`
+	Path     string      `json:"path"`
+	Name     string      `json:"name,omitempty"`
+	Count    int         `json:"count"`
+	Tags     []string    `json:"tags,omitempty"`
+	LastUsed time.Time   `json:"last_used,omitempty"`
+	Visits   []time.Time `json:"visits,omitempty"`
+}
+
+// MarshalJSON omits last_used when it is unset. `omitempty` has no effect
+// on time.Time (a non-empty struct), so without this every never-visited
+// bookmark would serialize the zero time instead of leaving the field out.
+func (b Bookmark) MarshalJSON() ([]byte, error) {
+	type alias Bookmark
+	out := struct {
+		alias
+		LastUsed *time.Time `json:"last_used,omitempty"`
+	}{alias: alias(b)}
+	if !b.LastUsed.IsZero() {
+		out.LastUsed = &b.LastUsed
+	}
+	return json.Marshal(out)
+}
+
+// sortMode selects how bookmarks are ordered in the TUI.
+type sortMode string
+
+const (
+	sortFrecency sortMode = "frecency"
+	sortCount    sortMode = "count"
+	sortAlpha    sortMode = "alpha"
+	sortRecent   sortMode = "recent"
+)
+
+// nextSortMode cycles through the sort modes in the order a user would
+// reach for them: frecency (the default) -> count -> alpha -> recent.
+func nextSortMode(m sortMode) sortMode {
+	switch m {
+	case sortFrecency:
+		return sortCount
+	case sortCount:
+		return sortAlpha
+	case sortAlpha:
+		return sortRecent
+	default:
+		return sortFrecency
+	}
 }
 
 // Config holds all bookmarks
@@ -27,13 +72,15 @@ type Config struct {
 
 // Model represents the TUI state
 type model struct {
+	store        Store
 	bookmarks    []Bookmark
-	filtered     []int // indices into bookmarks
+	filtered     []filterMatch
 	cursor       int
 	filter       string
 	editing      bool
 	editValue    string
 	selectedPath string // path to cd to after quit
+	sortMode     sortMode
 }
 
 var (
@@ -55,64 +102,121 @@ func getConfigPath() string {
 	return filepath.Join(home, ".config", "bk", "bookmarks.json")
 }
 
-func loadConfig() Config {
-	configPath := getConfigPath()
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return Config{Bookmarks: []Bookmark{}}
+// sortBookmarks orders bookmarks in place by mode. filter is the active TUI
+// filter text (may be ""); under sortFrecency it's passed through to
+// frecencyScore so a bookmark named exactly what was typed wins ties.
+func sortBookmarks(bookmarks []Bookmark, mode sortMode, filter string) {
+	switch mode {
+	case sortAlpha:
+		sort.Slice(bookmarks, func(i, j int) bool {
+			return displayName(bookmarks[i]) < displayName(bookmarks[j])
+		})
+	case sortRecent:
+		sort.Slice(bookmarks, func(i, j int) bool {
+			return bookmarks[i].LastUsed.After(bookmarks[j].LastUsed)
+		})
+	case sortCount:
+		sort.Slice(bookmarks, func(i, j int) bool {
+			return bookmarks[i].Count > bookmarks[j].Count
+		})
+	default: // sortFrecency
+		now := time.Now()
+		sort.Slice(bookmarks, func(i, j int) bool {
+			return frecencyScore(bookmarks[i], now, filter) > frecencyScore(bookmarks[j], now, filter)
+		})
 	}
-	var config Config
-	json.Unmarshal(data, &config)
-	return config
 }
 
-func saveConfig(config Config) error {
-	configPath := getConfigPath()
-	dir := filepath.Dir(configPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return err
+func displayName(b Bookmark) string {
+	if b.Name != "" {
+		return b.Name
 	}
-	return os.WriteFile(configPath, data, 0644)
+	return b.Path
 }
 
-func sortBookmarks(bookmarks []Bookmark) {
-	sort.Slice(bookmarks, func(i, j int) bool {
-		return bookmarks[i].Count > bookmarks[j].Count
-	})
+// filterMatch is one bookmark's result from filterBookmarks: its index
+// into the original slice, and (for a fuzzy filter) the rune offsets into
+// bookmarkMatchText(bookmark) that matched, for highlighting in View().
+type filterMatch struct {
+	index     int
+	positions []int
+}
+
+// bookmarkMatchText is the text fuzzy filtering matches against.
+func bookmarkMatchText(b Bookmark) string {
+	return b.Name + " " + b.Path
 }
 
-func filterBookmarks(bookmarks []Bookmark, filter string) []int {
+func filterBookmarks(bookmarks []Bookmark, filter string) []filterMatch {
 	if filter == "" {
-		indices := make([]int, len(bookmarks))
+		matches := make([]filterMatch, len(bookmarks))
 		for i := range bookmarks {
-			indices[i] = i
+			matches[i] = filterMatch{index: i}
+		}
+		return matches
+	}
+
+	// A leading "#" filters by tag instead of name/path, e.g. "#work"
+	if strings.HasPrefix(filter, "#") {
+		tag := strings.ToLower(strings.TrimPrefix(filter, "#"))
+		var matches []filterMatch
+		for i, b := range bookmarks {
+			if hasTagPrefix(b.Tags, tag) {
+				matches = append(matches, filterMatch{index: i})
+			}
 		}
-		return indices
+		return matches
+	}
+
+	type scored struct {
+		filterMatch
+		score int
 	}
-	filter = strings.ToLower(filter)
-	var indices []int
+	var candidates []scored
 	for i, b := range bookmarks {
-		name := strings.ToLower(b.Name)
-		path := strings.ToLower(b.Path)
-		if strings.Contains(name, filter) || strings.Contains(path, filter) {
-			indices = append(indices, i)
+		score, positions, ok := fuzzyMatch(filter, bookmarkMatchText(b))
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, scored{filterMatch{index: i, positions: positions}, score})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	matches := make([]filterMatch, len(candidates))
+	for i, c := range candidates {
+		matches[i] = c.filterMatch
+	}
+	return matches
+}
+
+// hasTagPrefix reports whether any of tags starts with prefix (case-insensitive).
+func hasTagPrefix(tags []string, prefix string) bool {
+	for _, t := range tags {
+		if strings.HasPrefix(strings.ToLower(t), prefix) {
+			return true
 		}
 	}
-	return indices
+	return false
 }
 
-func initialModel() model {
-	config := loadConfig()
-	sortBookmarks(config.Bookmarks)
+func initialModel(store Store, mode sortMode) model {
+	config, err := store.Load()
+	if err != nil {
+		config = Config{Bookmarks: []Bookmark{}}
+	}
+	if mode == "" {
+		mode = sortFrecency
+	}
+	sortBookmarks(config.Bookmarks, mode, "")
 	filtered := filterBookmarks(config.Bookmarks, "")
 	return model{
+		store:     store,
 		bookmarks: config.Bookmarks,
 		filtered:  filtered,
 		cursor:    0,
+		sortMode:  mode,
 	}
 }
 
@@ -127,10 +231,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch msg.Type {
 			case tea.KeyEnter:
 				if len(m.filtered) > 0 {
-					idx := m.filtered[m.cursor]
+					idx := m.filtered[m.cursor].index
+					old := m.bookmarks[idx]
 					m.bookmarks[idx].Name = m.editValue
-					config := Config{Bookmarks: m.bookmarks}
-					saveConfig(config)
+					m.store.Update(old.Path, m.bookmarks[idx])
 				}
 				m.editing = false
 				m.editValue = ""
@@ -172,10 +276,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case tea.KeyEnter:
 			if len(m.filtered) > 0 {
-				idx := m.filtered[m.cursor]
+				idx := m.filtered[m.cursor].index
 				m.bookmarks[idx].Count++
-				config := Config{Bookmarks: m.bookmarks}
-				saveConfig(config)
+				m.store.IncrementCount(m.bookmarks[idx].Path)
 				m.selectedPath = m.bookmarks[idx].Path
 				return m, tea.Quit
 			}
@@ -195,16 +298,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				case "e":
 					if len(m.filtered) > 0 {
 						m.editing = true
-						idx := m.filtered[m.cursor]
+						idx := m.filtered[m.cursor].index
 						m.editValue = m.bookmarks[idx].Name
 					}
 					return m, nil
 				case "d":
 					if len(m.filtered) > 0 {
-						idx := m.filtered[m.cursor]
+						idx := m.filtered[m.cursor].index
+						m.store.Delete(m.bookmarks[idx].Path)
 						m.bookmarks = append(m.bookmarks[:idx], m.bookmarks[idx+1:]...)
-						config := Config{Bookmarks: m.bookmarks}
-						saveConfig(config)
 						m.filtered = filterBookmarks(m.bookmarks, m.filter)
 						if m.cursor >= len(m.filtered) && m.cursor > 0 {
 							m.cursor--
@@ -221,6 +323,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.cursor--
 					}
 					return m, nil
+				case "s":
+					m.sortMode = nextSortMode(m.sortMode)
+					sortBookmarks(m.bookmarks, m.sortMode, m.filter)
+					m.filtered = filterBookmarks(m.bookmarks, m.filter)
+					m.cursor = 0
+					return m, nil
 				}
 			}
 			// Otherwise, add to filter
@@ -252,45 +360,57 @@ func (m model) View() string {
 	if len(m.filtered) == 0 {
 		s += dimStyle.Render("  No matches") + "\n"
 	} else {
-		for i, idx := range m.filtered {
-			b := m.bookmarks[idx]
-			displayName := b.Path
+		for i, fm := range m.filtered {
+			b := m.bookmarks[fm.index]
+			pathOffset := utf8.RuneCountInString(b.Name) + 1
+			nameText := renderHighlighted(b.Name, fm.positions, 0, lipgloss.NewStyle(), filterStyle)
+			pathText := renderHighlighted(b.Path, fm.positions, pathOffset, dimStyle, filterStyle)
+
+			displayName := pathText
 			if b.Name != "" {
-				displayName = b.Name
+				displayName = nameText
 			}
 
 			if i == m.cursor {
 				if b.Name != "" {
 					s += fmt.Sprintf("  > %s", renderSelected(displayName))
-					s += dimStyle.Render(fmt.Sprintf(" %s", b.Path))
+					s += " " + pathText
 				} else {
 					s += fmt.Sprintf("  > %s", renderSelected(displayName))
 				}
 			} else {
 				if b.Name != "" {
 					s += fmt.Sprintf("    %s", displayName)
-					s += dimStyle.Render(fmt.Sprintf(" %s", b.Path))
+					s += " " + pathText
 				} else {
 					s += fmt.Sprintf("    %s", displayName)
 				}
 			}
+			if len(b.Tags) > 0 {
+				s += dimStyle.Render(fmt.Sprintf(" #%s", strings.Join(b.Tags, " #")))
+			}
 			s += "\n"
 		}
 	}
 
-	s += "\n  ↑/↓ navigate • enter select • e rename • d delete • esc clear • q quit\n"
+	s += dimStyle.Render(fmt.Sprintf("\n  sort: %s (s to cycle)\n", m.sortMode))
+	s += "  ↑/↓ navigate • enter select • e rename • d delete • esc clear • q quit\n"
 
 	return s
 }
 
-func addBookmark() {
+func addBookmark(store Store) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
 		os.Exit(1)
 	}
 
-	config := loadConfig()
+	config, err := store.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading bookmarks: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Check if bookmark already exists
 	for _, b := range config.Bookmarks {
@@ -307,13 +427,7 @@ func addBookmark() {
 	alias, _ := reader.ReadString('\n')
 	alias = strings.TrimSpace(alias)
 
-	config.Bookmarks = append(config.Bookmarks, Bookmark{
-		Path:  cwd,
-		Name:  alias,
-		Count: 0,
-	})
-
-	if err := saveConfig(config); err != nil {
+	if err := store.Add(Bookmark{Path: cwd, Name: alias, Count: 0}); err != nil {
 		fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
 		os.Exit(1)
 	}
@@ -325,11 +439,130 @@ func addBookmark() {
 	}
 }
 
+// importFormat guesses the bookmark file format from a --format flag or,
+// failing that, the file extension.
+func importFormat(args []string, path string) string {
+	for _, a := range args {
+		if strings.HasPrefix(a, "--format=") {
+			return strings.TrimPrefix(a, "--format=")
+		}
+	}
+	if strings.EqualFold(filepath.Ext(path), ".html") || strings.EqualFold(filepath.Ext(path), ".htm") {
+		return "html"
+	}
+	return "xbel"
+}
+
+func importBookmarks(store Store, args []string) {
+	var path string
+	for _, a := range args {
+		if !strings.HasPrefix(a, "--") {
+			path = a
+		}
+	}
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "Usage: bk import <file> [--format=xbel|html]")
+		os.Exit(1)
+	}
+
+	var imported []Bookmark
+	var err error
+	switch importFormat(args, path) {
+	case "html":
+		imported, err = ImportNetscapeHTML(path)
+	case "xbel":
+		imported, err = ImportXBEL(path)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format: %s\n", importFormat(args, path))
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error importing bookmarks: %v\n", err)
+		os.Exit(1)
+	}
+
+	config, err := store.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading bookmarks: %v\n", err)
+		os.Exit(1)
+	}
+	existing := make(map[string]bool)
+	for _, b := range config.Bookmarks {
+		existing[b.Path] = true
+	}
+
+	added := 0
+	for _, b := range imported {
+		if existing[b.Path] {
+			continue
+		}
+		if err := store.Add(b); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving bookmark %s: %v\n", b.Path, err)
+			os.Exit(1)
+		}
+		existing[b.Path] = true
+		added++
+	}
+
+	fmt.Printf("Imported %d bookmark(s), skipped %d duplicate(s)\n", added, len(imported)-added)
+}
+
+func exportBookmarks(store Store, args []string) {
+	var path string
+	for _, a := range args {
+		if !strings.HasPrefix(a, "--") {
+			path = a
+		}
+	}
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "Usage: bk export <file> [--format=xbel]")
+		os.Exit(1)
+	}
+
+	config, err := store.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading bookmarks: %v\n", err)
+		os.Exit(1)
+	}
+	if err := ExportXBEL(path, config.Bookmarks); err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting bookmarks: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exported %d bookmark(s) to %s\n", len(config.Bookmarks), path)
+}
+
 func main() {
+	store, err := openStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening bookmark store: %v\n", err)
+		os.Exit(1)
+	}
+
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "add":
-			addBookmark()
+			addBookmark(store)
+			return
+		case "import":
+			importBookmarks(store, os.Args[2:])
+			return
+		case "export":
+			exportBookmarks(store, os.Args[2:])
+			return
+		case "server":
+			runServer(store, os.Args[2:])
+			return
+		case "list":
+			cmdList(store, os.Args[2:])
+			return
+		case "rm":
+			cmdRm(store, os.Args[2:])
+			return
+		case "go":
+			cmdGo(store, os.Args[2:])
+			return
+		case "rename":
+			cmdRename(store, os.Args[2:])
 			return
 		case "help", "--help", "-h":
 			fmt.Println("bk - directory bookmarks")
@@ -337,17 +570,40 @@ func main() {
 			fmt.Println("Usage:")
 			fmt.Println("  bk        Open bookmark selector")
 			fmt.Println("  bk add    Add current directory to bookmarks")
+			fmt.Println("  bk import <file> [--format=xbel|html]  Import bookmarks")
+			fmt.Println("  bk export <file> [--format=xbel]        Export bookmarks")
+			fmt.Println("  bk server [--port=8765]                 Start the local HTTP management server")
+			fmt.Println("  bk list [--json]                        List bookmarks, for scripting")
+			fmt.Println("  bk go <name|path|index>                 Print a bookmark's path, no TUI")
+			fmt.Println("  bk rm <name|path|index>                 Delete a bookmark")
+			fmt.Println("  bk rename <old> <new>                   Rename a bookmark")
+			fmt.Println("")
+			fmt.Println("  bk go/rm/rename exit 0 on success, 1 if not found, 2 if ambiguous.")
 			fmt.Println("")
 			fmt.Println("Keys:")
 			fmt.Println("  ↑/↓, j/k  Navigate")
 			fmt.Println("  Enter     Go to selected directory")
 			fmt.Println("  e         Edit bookmark name")
 			fmt.Println("  d         Delete bookmark")
+			fmt.Println("  #tag      Filter by tag")
+			fmt.Println("  s         Cycle sort (frecency/count/alpha/recent)")
 			fmt.Println("  q         Quit")
+			fmt.Println("")
+			fmt.Println("  --sort=frecency|count|alpha|recent  Set initial sort order")
+			fmt.Println("")
+			fmt.Println("Storage backend defaults to a JSON file; set BK_STORE=sqlite")
+			fmt.Println("or `backend = \"sqlite\"` in ~/.config/bk/config.toml to switch.")
 			return
 		}
 	}
 
+	mode := sortFrecency
+	for _, a := range os.Args[1:] {
+		if strings.HasPrefix(a, "--sort=") {
+			mode = sortMode(strings.TrimPrefix(a, "--sort="))
+		}
+	}
+
 	// Open /dev/tty for TUI so it works even when stdout is captured
 	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
 	if err != nil {
@@ -356,7 +612,7 @@ func main() {
 	}
 	defer tty.Close()
 
-	p := tea.NewProgram(initialModel(), tea.WithInput(tty), tea.WithOutput(tty))
+	p := tea.NewProgram(initialModel(store, mode), tea.WithInput(tty), tea.WithOutput(tty))
 	m, err := p.Run()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBookmarkMarshalJSONOmitsZeroLastUsed(t *testing.T) {
+	b := Bookmark{Path: "/tmp/project"}
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if strings.Contains(string(data), "last_used") {
+		t.Fatalf("expected last_used to be omitted for a never-visited bookmark, got %s", data)
+	}
+}
+
+func TestBookmarkMarshalJSONIncludesSetLastUsed(t *testing.T) {
+	b := Bookmark{Path: "/tmp/project", LastUsed: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !strings.Contains(string(data), "last_used") {
+		t.Fatalf("expected last_used to be present once set, got %s", data)
+	}
+}
+
+// TestSortBookmarksFrecencyBoostsExactFilterMatch guards against the
+// exact-match boost being wired to nothing: with an otherwise-identical
+// pair, the bookmark whose name exactly matches the active filter should
+// sort first once the filter is passed through to frecencyScore.
+func TestSortBookmarksFrecencyBoostsExactFilterMatch(t *testing.T) {
+	now := time.Now()
+	bookmarks := []Bookmark{
+		{Path: "/tmp/projects", Name: "projects", LastUsed: now, Count: 1},
+		{Path: "/tmp/project", Name: "project", LastUsed: now, Count: 1},
+	}
+
+	sortBookmarks(bookmarks, sortFrecency, "project")
+
+	if bookmarks[0].Name != "project" {
+		t.Fatalf("bookmarks[0].Name = %q, want %q (exact filter match should sort first)", bookmarks[0].Name, "project")
+	}
+}
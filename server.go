@@ -0,0 +1,348 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const defaultServerPort = 8765
+
+func getTokenPath() string {
+	return fmt.Sprintf("%s/token", getUserConfigDir())
+}
+
+// loadOrCreateToken returns the server's auth token, generating and
+// persisting a new random one on first run.
+func loadOrCreateToken() (string, error) {
+	path := getTokenPath()
+	if data, err := os.ReadFile(path); err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := os.MkdirAll(getUserConfigDir(), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(token), 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// bookmarkID/pathFromID turn a bookmark's path into an opaque URL segment
+// and back, since paths (the store's primary key) contain slashes.
+func bookmarkID(path string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(path))
+}
+
+func pathFromID(id string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return "", fmt.Errorf("invalid bookmark id: %w", err)
+	}
+	return string(data), nil
+}
+
+// apiBookmark is the over-the-wire representation of a Bookmark, adding
+// the opaque id the REST API addresses it by.
+type apiBookmark struct {
+	ID    string   `json:"id"`
+	Path  string   `json:"path"`
+	Name  string   `json:"name,omitempty"`
+	Count int      `json:"count"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func toAPIBookmark(b Bookmark) apiBookmark {
+	return apiBookmark{
+		ID:    bookmarkID(b.Path),
+		Path:  b.Path,
+		Name:  b.Name,
+		Count: b.Count,
+		Tags:  b.Tags,
+	}
+}
+
+type bkServer struct {
+	store Store
+	token string
+}
+
+func (srv *bkServer) requireToken(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if strings.HasPrefix(auth, "Bearer ") && constantTimeEquals(strings.TrimPrefix(auth, "Bearer "), srv.token) {
+		return true
+	}
+	return constantTimeEquals(r.URL.Query().Get("token"), srv.token)
+}
+
+// constantTimeEquals compares two tokens without leaking timing
+// information about where they first differ.
+func constantTimeEquals(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func (srv *bkServer) handleBookmarks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		config, err := srv.store.Load()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		out := make([]apiBookmark, 0, len(config.Bookmarks))
+		for _, b := range config.Bookmarks {
+			out = append(out, toAPIBookmark(b))
+		}
+		writeJSON(w, http.StatusOK, out)
+	case http.MethodPost:
+		var b Bookmark
+		if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		if b.Path == "" {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("path is required"))
+			return
+		}
+		if err := srv.store.Add(b); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, toAPIBookmark(b))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (srv *bkServer) handleBookmark(w http.ResponseWriter, r *http.Request, id string) {
+	path, err := pathFromID(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPatch:
+		config, err := srv.store.Load()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		existing, ok := findBookmark(config.Bookmarks, path)
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var patch struct {
+			Name *string   `json:"name"`
+			Tags *[]string `json:"tags"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err)
+			return
+		}
+		if patch.Name != nil {
+			existing.Name = *patch.Name
+		}
+		if patch.Tags != nil {
+			existing.Tags = *patch.Tags
+		}
+
+		if err := srv.store.Update(path, existing); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, toAPIBookmark(existing))
+	case http.MethodDelete:
+		if err := srv.store.Delete(path); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (srv *bkServer) handleVisit(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	path, err := pathFromID(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := srv.store.IncrementCount(path); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func findBookmark(bookmarks []Bookmark, path string) (Bookmark, bool) {
+	for _, b := range bookmarks {
+		if b.Path == path {
+			return b, true
+		}
+	}
+	return Bookmark{}, false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func (srv *bkServer) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !srv.requireToken(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, managementHTML, srv.token)
+	})
+
+	mux.HandleFunc("/bookmarks", func(w http.ResponseWriter, r *http.Request) {
+		if !srv.requireToken(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		srv.handleBookmarks(w, r)
+	})
+
+	mux.HandleFunc("/bookmarks/", func(w http.ResponseWriter, r *http.Request) {
+		if !srv.requireToken(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rest := strings.TrimPrefix(r.URL.Path, "/bookmarks/")
+		if id, ok := strings.CutSuffix(rest, "/visit"); ok {
+			srv.handleVisit(w, r, id)
+			return
+		}
+		srv.handleBookmark(w, r, rest)
+	})
+
+	return mux
+}
+
+// runServer starts the loopback-only management server used by request
+// chunk0-4: a small JSON REST API plus a minimal HTML UI for browsers.
+func runServer(store Store, args []string) {
+	port := defaultServerPort
+	for _, a := range args {
+		if p, ok := strings.CutPrefix(a, "--port="); ok {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --port value: %s\n", p)
+				os.Exit(1)
+			}
+			port = n
+		}
+	}
+
+	token, err := loadOrCreateToken()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error preparing server token: %v\n", err)
+		os.Exit(1)
+	}
+
+	srv := &bkServer{store: store, token: token}
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	fmt.Printf("bk server listening on http://%s/?token=%s\n", addr, token)
+	fmt.Printf("API token stored at %s\n", getTokenPath())
+	if err := http.ListenAndServe(addr, srv.routes()); err != nil {
+		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+const managementHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>bk bookmarks</title>
+<style>
+  body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 2rem auto; color: #222; }
+  li { display: flex; justify-content: space-between; align-items: center; padding: 0.4rem 0; border-bottom: 1px solid #eee; }
+  .path { color: #888; font-size: 0.85em; margin-left: 0.5rem; }
+  button { margin-left: 0.5rem; }
+</style>
+</head>
+<body>
+<h1>bk bookmarks</h1>
+<ul id="list"></ul>
+<script>
+const token = %q;
+async function api(path, opts) {
+  opts = opts || {};
+  opts.headers = Object.assign({'Authorization': 'Bearer ' + token, 'Content-Type': 'application/json'}, opts.headers || {});
+  return fetch(path, opts);
+}
+async function load() {
+  const res = await api('/bookmarks');
+  const bookmarks = await res.json();
+  const list = document.getElementById('list');
+  list.innerHTML = '';
+  for (const b of bookmarks) {
+    const li = document.createElement('li');
+    const label = document.createElement('span');
+    label.textContent = (b.name || b.path);
+    const path = document.createElement('span');
+    path.className = 'path';
+    path.textContent = b.path;
+    label.appendChild(path);
+    const rename = document.createElement('button');
+    rename.textContent = 'Rename';
+    rename.onclick = async () => {
+      const name = prompt('New name', b.name || '');
+      if (name === null) return;
+      await api('/bookmarks/' + b.id, {method: 'PATCH', body: JSON.stringify({name})});
+      load();
+    };
+    const del = document.createElement('button');
+    del.textContent = 'Delete';
+    del.onclick = async () => {
+      await api('/bookmarks/' + b.id, {method: 'DELETE'});
+      load();
+    };
+    li.appendChild(label);
+    li.appendChild(rename);
+    li.appendChild(del);
+    list.appendChild(li);
+  }
+}
+load();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireTokenAcceptsBearerHeader(t *testing.T) {
+	srv := &bkServer{token: "secret"}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	if !srv.requireToken(r) {
+		t.Fatalf("expected matching bearer token to be accepted")
+	}
+}
+
+func TestRequireTokenAcceptsQueryParam(t *testing.T) {
+	srv := &bkServer{token: "secret"}
+	r := httptest.NewRequest(http.MethodGet, "/?token=secret", nil)
+	if !srv.requireToken(r) {
+		t.Fatalf("expected matching query token to be accepted")
+	}
+}
+
+func TestRequireTokenRejectsWrongToken(t *testing.T) {
+	srv := &bkServer{token: "secret"}
+	r := httptest.NewRequest(http.MethodGet, "/?token=wrong", nil)
+	if srv.requireToken(r) {
+		t.Fatalf("expected mismatched token to be rejected")
+	}
+}
@@ -0,0 +1,475 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	_ "modernc.org/sqlite"
+)
+
+// Store is the persistence backend for bookmarks. bk ships a JSON file
+// store (the historical default, still the right choice for a single
+// shell) and a SQLite store for safe concurrent writes from many shells.
+type Store interface {
+	Load() (Config, error)
+	Save(Config) error
+	Add(b Bookmark) error
+	Delete(path string) error
+	Update(path string, b Bookmark) error
+	IncrementCount(path string) error
+}
+
+// bkConfig is the contents of ~/.config/bk/config.toml.
+type bkConfig struct {
+	Backend string `toml:"backend"`
+}
+
+func getUserConfigDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "bk")
+}
+
+func getTomlConfigPath() string {
+	return filepath.Join(getUserConfigDir(), "config.toml")
+}
+
+func getSQLitePath() string {
+	return filepath.Join(getUserConfigDir(), "bookmarks.db")
+}
+
+// configuredBackend reads the `backend` key from config.toml, returning ""
+// if the file or key is absent.
+func configuredBackend() string {
+	data, err := os.ReadFile(getTomlConfigPath())
+	if err != nil {
+		return ""
+	}
+	var cfg bkConfig
+	if _, err := toml.Decode(string(data), &cfg); err != nil {
+		return ""
+	}
+	return cfg.Backend
+}
+
+// openStore selects a backend: the BK_STORE env var wins, then the
+// `backend` key in config.toml, defaulting to the JSON file store.
+func openStore() (Store, error) {
+	backend := os.Getenv("BK_STORE")
+	if backend == "" {
+		backend = configuredBackend()
+	}
+	switch backend {
+	case "", "json":
+		return newJSONStore(getConfigPath()), nil
+	case "sqlite":
+		return newSQLiteStore(getSQLitePath())
+	default:
+		return nil, fmt.Errorf("unknown BK_STORE backend: %s", backend)
+	}
+}
+
+// jsonStore is the original whole-file JSON store. Every mutation reads
+// the file, changes it in memory, and rewrites it, so it is not safe
+// against concurrent `bk add` from multiple shells.
+type jsonStore struct {
+	path string
+}
+
+func newJSONStore(path string) *jsonStore {
+	return &jsonStore{path: path}
+}
+
+func (s *jsonStore) Load() (Config, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Config{Bookmarks: []Bookmark{}}, nil
+		}
+		return Config{}, err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+func (s *jsonStore) Save(config Config) error {
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+func (s *jsonStore) Add(b Bookmark) error {
+	config, err := s.Load()
+	if err != nil {
+		return err
+	}
+	config.Bookmarks = append(config.Bookmarks, b)
+	return s.Save(config)
+}
+
+func (s *jsonStore) Delete(path string) error {
+	config, err := s.Load()
+	if err != nil {
+		return err
+	}
+	for i, b := range config.Bookmarks {
+		if b.Path == path {
+			config.Bookmarks = append(config.Bookmarks[:i], config.Bookmarks[i+1:]...)
+			return s.Save(config)
+		}
+	}
+	return fmt.Errorf("bookmark not found: %s", path)
+}
+
+func (s *jsonStore) Update(path string, updated Bookmark) error {
+	config, err := s.Load()
+	if err != nil {
+		return err
+	}
+	for i, b := range config.Bookmarks {
+		if b.Path == path {
+			config.Bookmarks[i] = updated
+			return s.Save(config)
+		}
+	}
+	return fmt.Errorf("bookmark not found: %s", path)
+}
+
+func (s *jsonStore) IncrementCount(path string) error {
+	config, err := s.Load()
+	if err != nil {
+		return err
+	}
+	for i, b := range config.Bookmarks {
+		if b.Path == path {
+			recordVisit(&config.Bookmarks[i], time.Now())
+			return s.Save(config)
+		}
+	}
+	return fmt.Errorf("bookmark not found: %s", path)
+}
+
+// sqliteStore persists bookmarks in a SQLite database, giving atomic
+// per-row writes instead of a whole-file rewrite. Tags live in a separate
+// join table. The schema is created on first use; there is no history to
+// migrate yet, so a bigger project would reach for something like goose
+// once it does.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// sqliteDSN appends pragmas so concurrent writers from multiple shells
+// block and retry instead of failing immediately with SQLITE_BUSY: a
+// 5s busy timeout, and WAL so readers don't block behind a writer.
+func sqliteDSN(path string) string {
+	return fmt.Sprintf("%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(wal)", path)
+}
+
+func newSQLiteStore(path string) (*sqliteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", sqliteDSN(path))
+	if err != nil {
+		return nil, err
+	}
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func migrateSQLite(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS bookmarks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			path TEXT UNIQUE NOT NULL,
+			name TEXT NOT NULL DEFAULT '',
+			count INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL,
+			last_used_at DATETIME
+		);
+		CREATE TABLE IF NOT EXISTS tags (
+			bookmark_id INTEGER NOT NULL REFERENCES bookmarks(id) ON DELETE CASCADE,
+			tag TEXT NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS visits (
+			bookmark_id INTEGER NOT NULL REFERENCES bookmarks(id) ON DELETE CASCADE,
+			visited_at DATETIME NOT NULL
+		);
+	`)
+	return err
+}
+
+// replaceVisits overwrites a bookmark's visit history with visits, trimmed
+// to maxVisitHistory, matching the ring buffer kept in memory by Bookmark.
+func replaceVisits(tx *sql.Tx, bookmarkID int64, visits []time.Time) error {
+	if _, err := tx.Exec(`DELETE FROM visits WHERE bookmark_id = ?`, bookmarkID); err != nil {
+		return err
+	}
+	if len(visits) > maxVisitHistory {
+		visits = visits[len(visits)-maxVisitHistory:]
+	}
+	for _, v := range visits {
+		if _, err := tx.Exec(`INSERT INTO visits (bookmark_id, visited_at) VALUES (?, ?)`, bookmarkID, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) Load() (Config, error) {
+	rows, err := s.db.Query(`SELECT id, path, name, count, last_used_at FROM bookmarks ORDER BY id`)
+	if err != nil {
+		return Config{}, err
+	}
+	defer rows.Close()
+
+	byID := map[int64]*Bookmark{}
+	var order []int64
+	for rows.Next() {
+		var id int64
+		var b Bookmark
+		var lastUsed sql.NullTime
+		if err := rows.Scan(&id, &b.Path, &b.Name, &b.Count, &lastUsed); err != nil {
+			return Config{}, err
+		}
+		if lastUsed.Valid {
+			b.LastUsed = lastUsed.Time
+		}
+		byID[id] = &b
+		order = append(order, id)
+	}
+	if err := rows.Err(); err != nil {
+		return Config{}, err
+	}
+
+	tagRows, err := s.db.Query(`SELECT bookmark_id, tag FROM tags ORDER BY bookmark_id`)
+	if err != nil {
+		return Config{}, err
+	}
+	defer tagRows.Close()
+	for tagRows.Next() {
+		var id int64
+		var tag string
+		if err := tagRows.Scan(&id, &tag); err != nil {
+			return Config{}, err
+		}
+		if b, ok := byID[id]; ok {
+			b.Tags = append(b.Tags, tag)
+		}
+	}
+
+	visitRows, err := s.db.Query(`SELECT bookmark_id, visited_at FROM visits ORDER BY bookmark_id, visited_at`)
+	if err != nil {
+		return Config{}, err
+	}
+	defer visitRows.Close()
+	for visitRows.Next() {
+		var id int64
+		var visitedAt time.Time
+		if err := visitRows.Scan(&id, &visitedAt); err != nil {
+			return Config{}, err
+		}
+		if b, ok := byID[id]; ok {
+			b.Visits = append(b.Visits, visitedAt)
+		}
+	}
+
+	config := Config{Bookmarks: make([]Bookmark, 0, len(order))}
+	for _, id := range order {
+		config.Bookmarks = append(config.Bookmarks, *byID[id])
+	}
+	return config, nil
+}
+
+// Save replaces the full bookmark set. It exists to satisfy the Store
+// interface for bulk replacement; import adds bookmarks one at a time
+// through Add, and day-to-day mutations should likewise use
+// Add/Delete/Update/IncrementCount, which touch one row.
+func (s *sqliteStore) Save(config Config) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM tags`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM bookmarks`); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, b := range config.Bookmarks {
+		res, err := tx.Exec(
+			`INSERT INTO bookmarks (path, name, count, created_at, updated_at, last_used_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			b.Path, b.Name, b.Count, now, now, nullTime(b.LastUsed),
+		)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, tag := range b.Tags {
+			if _, err := tx.Exec(`INSERT INTO tags (bookmark_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+				return err
+			}
+		}
+		if err := replaceVisits(tx, id, b.Visits); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+func (s *sqliteStore) Add(b Bookmark) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	res, err := tx.Exec(
+		`INSERT INTO bookmarks (path, name, count, created_at, updated_at, last_used_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		b.Path, b.Name, b.Count, now, now, nullTime(b.LastUsed),
+	)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for _, tag := range b.Tags {
+		if _, err := tx.Exec(`INSERT INTO tags (bookmark_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+			return err
+		}
+	}
+	if err := replaceVisits(tx, id, b.Visits); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) Delete(path string) error {
+	res, err := s.db.Exec(`DELETE FROM bookmarks WHERE path = ?`, path)
+	if err != nil {
+		return err
+	}
+	return checkRowsAffected(res, path)
+}
+
+func (s *sqliteStore) Update(path string, updated Bookmark) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`UPDATE bookmarks SET path = ?, name = ?, count = ?, updated_at = ?, last_used_at = ? WHERE path = ?`,
+		updated.Path, updated.Name, updated.Count, time.Now(), nullTime(updated.LastUsed), path,
+	)
+	if err != nil {
+		return err
+	}
+	if err := checkRowsAffected(res, path); err != nil {
+		return err
+	}
+
+	var id int64
+	if err := tx.QueryRow(`SELECT id FROM bookmarks WHERE path = ?`, updated.Path).Scan(&id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM tags WHERE bookmark_id = ?`, id); err != nil {
+		return err
+	}
+	for _, tag := range updated.Tags {
+		if _, err := tx.Exec(`INSERT INTO tags (bookmark_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+			return err
+		}
+	}
+	if err := replaceVisits(tx, id, updated.Visits); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// IncrementCount records a visit the same way jsonStore.IncrementCount
+// does via recordVisit: bump count/last_used_at and append to the capped
+// visits ring buffer, so frecencyScore gets the same bucketed history
+// regardless of backend.
+func (s *sqliteStore) IncrementCount(path string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	res, err := tx.Exec(
+		`UPDATE bookmarks SET count = count + 1, updated_at = ?, last_used_at = ? WHERE path = ?`,
+		now, now, path,
+	)
+	if err != nil {
+		return err
+	}
+	if err := checkRowsAffected(res, path); err != nil {
+		return err
+	}
+
+	var id int64
+	if err := tx.QueryRow(`SELECT id FROM bookmarks WHERE path = ?`, path).Scan(&id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO visits (bookmark_id, visited_at) VALUES (?, ?)`, id, now); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`DELETE FROM visits WHERE bookmark_id = ? AND rowid NOT IN (
+			SELECT rowid FROM visits WHERE bookmark_id = ? ORDER BY visited_at DESC LIMIT ?
+		)`,
+		id, id, maxVisitHistory,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func checkRowsAffected(res sql.Result, path string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("bookmark not found: %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// xbelDocument models the subset of the XBEL format (as produced by Amfora
+// and most browsers' bookmark exporters) that bk round-trips: bookmarks,
+// optionally nested in folders.
+type xbelDocument struct {
+	XMLName   xml.Name       `xml:"xbel"`
+	Version   string         `xml:"version,attr"`
+	Bookmarks []xbelBookmark `xml:"bookmark"`
+	Folders   []xbelFolder   `xml:"folder"`
+}
+
+type xbelFolder struct {
+	Title     string         `xml:"title"`
+	Bookmarks []xbelBookmark `xml:"bookmark"`
+	Folders   []xbelFolder   `xml:"folder"`
+}
+
+type xbelBookmark struct {
+	Href  string `xml:"href,attr"`
+	Title string `xml:"title"`
+}
+
+// ImportXBEL reads an XBEL file and returns the bookmarks it contains.
+// Each enclosing folder title, outermost first, becomes one tag on every
+// bookmark found beneath it.
+func ImportXBEL(path string) ([]Bookmark, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading XBEL file: %w", err)
+	}
+
+	var doc xbelDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing XBEL file: %w", err)
+	}
+
+	var bookmarks []Bookmark
+	for _, b := range doc.Bookmarks {
+		bookmarks = append(bookmarks, xbelToBookmark(b, nil))
+	}
+	for _, f := range doc.Folders {
+		bookmarks = append(bookmarks, collectXBELFolder(f, nil)...)
+	}
+	return bookmarks, nil
+}
+
+func collectXBELFolder(f xbelFolder, parentTags []string) []Bookmark {
+	tags := parentTags
+	if f.Title != "" {
+		tags = append(append([]string{}, parentTags...), f.Title)
+	}
+
+	var bookmarks []Bookmark
+	for _, b := range f.Bookmarks {
+		bookmarks = append(bookmarks, xbelToBookmark(b, tags))
+	}
+	for _, sub := range f.Folders {
+		bookmarks = append(bookmarks, collectXBELFolder(sub, tags)...)
+	}
+	return bookmarks
+}
+
+func xbelToBookmark(b xbelBookmark, tags []string) Bookmark {
+	return Bookmark{
+		Path: fileURLToPath(b.Href),
+		Name: b.Title,
+		Tags: tags,
+	}
+}
+
+// xbelFolderNode is an in-memory tree used to turn each bookmark's Tags
+// slice into a path of nested <folder> elements on export, the mirror
+// image of collectXBELFolder's nested-folder-to-tag-path import logic.
+type xbelFolderNode struct {
+	children  map[string]*xbelFolderNode
+	order     []string
+	bookmarks []Bookmark
+}
+
+func newXBELFolderNode() *xbelFolderNode {
+	return &xbelFolderNode{children: map[string]*xbelFolderNode{}}
+}
+
+func (n *xbelFolderNode) insert(b Bookmark) {
+	cur := n
+	for _, tag := range b.Tags {
+		child, ok := cur.children[tag]
+		if !ok {
+			child = newXBELFolderNode()
+			cur.children[tag] = child
+			cur.order = append(cur.order, tag)
+		}
+		cur = child
+	}
+	cur.bookmarks = append(cur.bookmarks, b)
+}
+
+// ExportXBEL writes bookmarks to path in XBEL format. Each bookmark's full
+// Tags slice becomes a path of nested <folder> elements, so multi-tag
+// bookmarks round-trip through ImportXBEL losslessly.
+func ExportXBEL(path string, bookmarks []Bookmark) error {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<!DOCTYPE xbel PUBLIC "+//IDN python.org//DTD XBEL 1.0//EN//XML" "http://pyxml.sourceforge.net/topics/dtds/xbel.dtd">` + "\n")
+	sb.WriteString(`<xbel version="1.0">` + "\n")
+
+	root := newXBELFolderNode()
+	for _, b := range bookmarks {
+		root.insert(b)
+	}
+	writeXBELFolderNode(&sb, "  ", root)
+
+	sb.WriteString("</xbel>\n")
+	return os.WriteFile(path, []byte(sb.String()), 0644)
+}
+
+func writeXBELFolderNode(sb *strings.Builder, indent string, n *xbelFolderNode) {
+	for _, b := range n.bookmarks {
+		writeXBELBookmark(sb, indent, b)
+	}
+	for _, tag := range n.order {
+		sb.WriteString(fmt.Sprintf("%s<folder>\n%s  <title>%s</title>\n", indent, indent, xmlEscape(tag)))
+		writeXBELFolderNode(sb, indent+"  ", n.children[tag])
+		sb.WriteString(fmt.Sprintf("%s</folder>\n", indent))
+	}
+}
+
+// writeXBELBookmark omits <title> entirely for an unnamed bookmark, rather
+// than falling back to the path: xbelToBookmark maps <title> straight to
+// Name, so writing the path as a placeholder title would turn an unnamed
+// bookmark into one named after its own path on the next import.
+func writeXBELBookmark(sb *strings.Builder, indent string, b Bookmark) {
+	if b.Name == "" {
+		sb.WriteString(fmt.Sprintf("%s<bookmark href=\"%s\"/>\n", indent, xmlEscape(pathToFileURL(b.Path))))
+		return
+	}
+	sb.WriteString(fmt.Sprintf("%s<bookmark href=\"%s\"><title>%s</title></bookmark>\n",
+		indent, xmlEscape(pathToFileURL(b.Path)), xmlEscape(b.Name)))
+}
+
+func xmlEscape(s string) string {
+	var buf strings.Builder
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// pathToFileURL and fileURLToPath percent-encode/decode the path per RFC
+// 3986, so a path containing a space or other reserved character round-trips
+// correctly through XBEL files produced by other tools (Amfora, browsers),
+// not just bk's own export/import.
+func pathToFileURL(path string) string {
+	return "file://" + (&url.URL{Path: path}).EscapedPath()
+}
+
+func fileURLToPath(href string) string {
+	rest := strings.TrimPrefix(href, "file://")
+	if parsed, err := url.Parse(rest); err == nil {
+		return parsed.Path
+	}
+	return rest
+}
+
+var (
+	netscapeFolderRe    = regexp.MustCompile(`(?i)<H3[^>]*>(.*?)</H3>`)
+	netscapeBookmarkRe  = regexp.MustCompile(`(?i)<A\s+HREF="([^"]+)"[^>]*>(.*?)</A>`)
+	netscapeFolderEndRe = regexp.MustCompile(`(?i)</DL>`)
+)
+
+// ImportNetscapeHTML reads a Netscape-format bookmarks.html file (exported
+// by most desktop and mobile browsers) and returns its bookmarks, using
+// enclosing <H3> folders as tags. The format predates well-formed HTML, so
+// this is a line-oriented scan rather than a full parse.
+func ImportNetscapeHTML(path string) ([]Bookmark, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading bookmarks file: %w", err)
+	}
+
+	var bookmarks []Bookmark
+	var folderStack []string
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := netscapeFolderRe.FindStringSubmatch(line); m != nil {
+			folderStack = append(folderStack, htmlUnescape(strings.TrimSpace(m[1])))
+			continue
+		}
+		if m := netscapeBookmarkRe.FindStringSubmatch(line); m != nil {
+			href := m[1]
+			if !strings.HasPrefix(href, "file://") {
+				continue // skip non-directory (http/https) bookmarks
+			}
+			bookmarks = append(bookmarks, Bookmark{
+				Path: fileURLToPath(href),
+				Name: htmlUnescape(strings.TrimSpace(m[2])),
+				Tags: append([]string{}, folderStack...),
+			})
+			continue
+		}
+		if netscapeFolderEndRe.MatchString(line) && len(folderStack) > 0 {
+			folderStack = folderStack[:len(folderStack)-1]
+		}
+	}
+
+	return bookmarks, nil
+}
+
+func htmlUnescape(s string) string {
+	replacer := strings.NewReplacer(
+		"&amp;", "&",
+		"&lt;", "<",
+		"&gt;", ">",
+		"&quot;", `"`,
+		"&#39;", "'",
+	)
+	return replacer.Replace(s)
+}
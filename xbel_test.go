@@ -0,0 +1,82 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExportImportXBELRoundTripsMultipleTags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks.xbel")
+	original := []Bookmark{
+		{Path: "/tmp/project", Name: "project", Tags: []string{"work", "sub"}},
+	}
+
+	if err := ExportXBEL(path, original); err != nil {
+		t.Fatalf("ExportXBEL: %v", err)
+	}
+	got, err := ImportXBEL(path)
+	if err != nil {
+		t.Fatalf("ImportXBEL: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d bookmarks, want 1", len(got))
+	}
+	want := []string{"work", "sub"}
+	if len(got[0].Tags) != len(want) {
+		t.Fatalf("Tags = %v, want %v", got[0].Tags, want)
+	}
+	for i, tag := range want {
+		if got[0].Tags[i] != tag {
+			t.Fatalf("Tags = %v, want %v", got[0].Tags, want)
+		}
+	}
+}
+
+func TestExportImportXBELRoundTripsPathWithSpace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks.xbel")
+	original := []Bookmark{{Path: "/tmp/my project", Name: "my project"}}
+
+	if err := ExportXBEL(path, original); err != nil {
+		t.Fatalf("ExportXBEL: %v", err)
+	}
+	got, err := ImportXBEL(path)
+	if err != nil {
+		t.Fatalf("ImportXBEL: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d bookmarks, want 1", len(got))
+	}
+	if got[0].Path != "/tmp/my project" {
+		t.Fatalf("Path = %q, want %q", got[0].Path, "/tmp/my project")
+	}
+}
+
+// TestFileURLToPathDecodesPercentEncoding guards against importing XBEL
+// files produced by other tools (Amfora, browsers), which percent-encode
+// paths per RFC 3986 rather than bk's own (formerly plain-concatenation)
+// convention.
+func TestFileURLToPathDecodesPercentEncoding(t *testing.T) {
+	got := fileURLToPath("file:///tmp/my%20project")
+	if got != "/tmp/my project" {
+		t.Fatalf("fileURLToPath = %q, want %q", got, "/tmp/my project")
+	}
+}
+
+func TestExportImportXBELRoundTripsUnnamedBookmark(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks.xbel")
+	original := []Bookmark{{Path: "/tmp/unnamed"}}
+
+	if err := ExportXBEL(path, original); err != nil {
+		t.Fatalf("ExportXBEL: %v", err)
+	}
+	got, err := ImportXBEL(path)
+	if err != nil {
+		t.Fatalf("ImportXBEL: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d bookmarks, want 1", len(got))
+	}
+	if got[0].Name != "" {
+		t.Fatalf("Name = %q, want empty (unnamed bookmark should stay unnamed)", got[0].Name)
+	}
+}